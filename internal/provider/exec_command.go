@@ -0,0 +1,130 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// stderrTailLimit bounds how much stderr output is kept for inclusion in a
+// failed command's error message.
+const stderrTailLimit = 16 * 1024
+
+// tailBuffer is an io.Writer that retains only the last limit bytes written
+// to it.
+type tailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	buf   []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.limit {
+		t.buf = t.buf[len(t.buf)-t.limit:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
+
+// runBuildCommand runs executable with args in dir, streaming stdout through
+// tflog.Info and stderr through tflog.Warn line by line, both tagged with
+// the command's index so interleaved logs from multiple commands stay
+// attributable. If timeout is non-zero the command is killed once it elapses.
+// On failure the returned error includes the last stderrTailLimit bytes of
+// stderr.
+func runBuildCommand(ctx context.Context, executable string, args []string, dir string, env []string, timeout time.Duration, index int) error {
+	cmdCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		cmdCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	command := exec.CommandContext(cmdCtx, executable, args...)
+	command.Dir = dir
+	if len(env) > 0 {
+		command.Env = append(os.Environ(), env...)
+	}
+
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("could not create stdout pipe: %w", err)
+	}
+	stderrReader, stderrWriter, err := os.Pipe()
+	if err != nil {
+		stdoutReader.Close()
+		stdoutWriter.Close()
+		return fmt.Errorf("could not create stderr pipe: %w", err)
+	}
+	command.Stdout = stdoutWriter
+	command.Stderr = stderrWriter
+
+	if err := command.Start(); err != nil {
+		stdoutReader.Close()
+		stdoutWriter.Close()
+		stderrReader.Close()
+		stderrWriter.Close()
+		return fmt.Errorf("could not start command %s: %w", command.String(), err)
+	}
+
+	// The write ends now belong to the child; close our copies so the reader
+	// goroutines observe EOF once the child exits. The read ends are ours for
+	// the lifetime of this call and must be closed too, or every build
+	// command leaks 2 fds.
+	stdoutWriter.Close()
+	stderrWriter.Close()
+	defer stdoutReader.Close()
+	defer stderrReader.Close()
+
+	stderrTail := &tailBuffer{limit: stderrTailLimit}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		streamLines(ctx, stdoutReader, index, tflog.Info)
+	}()
+	go func() {
+		defer wg.Done()
+		streamLines(ctx, io.TeeReader(stderrReader, stderrTail), index, tflog.Warn)
+	}()
+	wg.Wait()
+
+	if err := command.Wait(); err != nil {
+		if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("command %s timed out after %s\nstderr:\n%s", command.String(), timeout, stderrTail.String())
+		}
+		return fmt.Errorf("command %s failed: %w\nstderr:\n%s", command.String(), err, stderrTail.String())
+	}
+
+	return nil
+}
+
+// streamLines scans r line by line and forwards each line to log, tagging it
+// with the command's index.
+func streamLines(ctx context.Context, r io.Reader, index int, log func(context.Context, string, ...map[string]interface{})) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		log(ctx, scanner.Text(), map[string]interface{}{"command_index": index})
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunBuildCommand_Success(t *testing.T) {
+	err := runBuildCommand(context.Background(), "sh", []string{"-c", "echo hello"}, t.TempDir(), nil, 0, 0)
+	if err != nil {
+		t.Fatalf("runBuildCommand returned unexpected error: %s", err)
+	}
+}
+
+func TestRunBuildCommand_FailureIncludesStderrTail(t *testing.T) {
+	err := runBuildCommand(context.Background(), "sh", []string{"-c", "echo boom >&2; exit 1"}, t.TempDir(), nil, 0, 0)
+	if err == nil {
+		t.Fatal("expected an error from a failing command")
+	}
+	if !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected error to include captured stderr, got: %s", err)
+	}
+}
+
+func TestRunBuildCommand_Timeout(t *testing.T) {
+	err := runBuildCommand(context.Background(), "sh", []string{"-c", "sleep 5"}, t.TempDir(), nil, 50*time.Millisecond, 0)
+	if err == nil {
+		t.Fatal("expected an error from a command exceeding its timeout")
+	}
+	if !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got: %s", err)
+	}
+}
+
+func TestRunBuildCommand_PassesEnv(t *testing.T) {
+	err := runBuildCommand(context.Background(), "sh", []string{"-c", `test "$FOO" = "bar"`}, t.TempDir(), []string{"FOO=bar"}, 0, 0)
+	if err != nil {
+		t.Fatalf("expected env to be passed through, got error: %s", err)
+	}
+}
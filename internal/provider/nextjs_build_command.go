@@ -5,24 +5,25 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listdefault"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"os"
-	"os/exec"
 	path2 "path"
 	"strings"
+	"time"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &BuildCommand{}
 var _ resource.ResourceWithImportState = &BuildCommand{}
+var _ resource.ResourceWithModifyPlan = &BuildCommand{}
 
 func NewBuildCommand() resource.Resource {
 	return &BuildCommand{}
@@ -30,14 +31,64 @@ func NewBuildCommand() resource.Resource {
 
 // BuildCommand defines the resource implementation.
 type BuildCommand struct {
-	executable string
+	// executable and packageManager carry provider-level overrides. Either
+	// may be empty, meaning it must be resolved from the resource's own
+	// source_path instead.
+	executable     string
+	packageManager string
 }
 
 // BuildCommandModel describes the resource data model.
 type BuildCommandModel struct {
-	SourcePath types.String `tfsdk:"source_path"`
-	Commands   types.List   `tfsdk:"commands"`
-	Data       types.List   `tfsdk:"data"`
+	SourcePath             types.String `tfsdk:"source_path"`
+	Commands               types.List   `tfsdk:"commands"`
+	IgnoreMissingArtifacts types.Bool   `tfsdk:"ignore_missing_artifacts"`
+	Triggers               types.Map    `tfsdk:"triggers"`
+	Exclude                types.List   `tfsdk:"exclude"`
+	SourceHash             types.String `tfsdk:"source_hash"`
+	Env                    types.Map    `tfsdk:"env"`
+	Timeout                types.String `tfsdk:"timeout"`
+	Data                   types.List   `tfsdk:"data"`
+	ArtifactsBaseline      types.List   `tfsdk:"artifacts_baseline"`
+}
+
+// buildCommandArtifact mirrors a single entry of the `data` list attribute,
+// used to read and rewrite stored state during drift detection.
+type buildCommandArtifact struct {
+	Path         types.String `tfsdk:"path"`
+	SHA256       types.String `tfsdk:"sha256"`
+	FunctionName types.String `tfsdk:"function_name"`
+}
+
+// dataElementTypes is the attr.Type map shared by every schema.ListNestedAttribute
+// entry in the `data` attribute.
+var dataElementTypes = map[string]attr.Type{
+	"path":          types.StringType,
+	"sha256":        types.StringType,
+	"function_name": types.StringType,
+}
+
+// artifactAttributes returns the nested attributes shared by `data` and
+// `artifacts_baseline`, which both hold one entry per built function
+// artifact.
+func artifactAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"path": schema.StringAttribute{
+			MarkdownDescription: "Absolute path to the function's packaged artifact zip.",
+			Optional:            true,
+			Computed:            true,
+		},
+		"sha256": schema.StringAttribute{
+			MarkdownDescription: "SHA-256 digest of the artifact, suitable for `aws_lambda_function.source_code_hash`.",
+			Optional:            true,
+			Computed:            true,
+		},
+		"function_name": schema.StringAttribute{
+			MarkdownDescription: "Logical name of the function.",
+			Optional:            true,
+			Computed:            true,
+		},
+	}
 }
 
 func (r *BuildCommand) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -56,57 +107,191 @@ func (r *BuildCommand) Schema(ctx context.Context, req resource.SchemaRequest, r
 				Required:            true,
 			},
 			"commands": schema.ListAttribute{
-				MarkdownDescription: "Example configurable attribute with default value",
+				MarkdownDescription: "Commands run against the resolved package manager executable, in order. Defaults to that package manager's conventional install/build/package sequence (see `package_manager` on the provider) when left unset.",
 				Optional:            true,
 				Computed:            true,
-				Default:             listdefault.StaticValue(types.ListValueMust(types.StringType, []attr.Value{types.StringValue("install --cache .npm --prefer-offline"), types.StringValue("run-script build"), types.StringValue("run-script package")})),
 				ElementType:         types.StringType,
 			},
+			"ignore_missing_artifacts": schema.BoolAttribute{
+				MarkdownDescription: "When true, a build artifact that has disappeared from disk is left untouched in state instead of being dropped to force a replace on the next plan. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"triggers": schema.MapAttribute{
+				MarkdownDescription: "Arbitrary map of values that, when changed, forces the resource to be replaced and the build commands to be re-run. Works like `triggers` on `null_resource`.",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"exclude": schema.ListAttribute{
+				MarkdownDescription: "Top-level entries under `source_path` to ignore when computing `source_hash`, similar to a `.terraformignore`. Defaults to `[\"node_modules\", \".next\", \".serverless\", \".npm\"]`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             listdefault.StaticValue(types.ListValueMust(types.StringType, excludeDefaultValues())),
+				ElementType:         types.StringType,
+			},
+			"source_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 hash of the contents of `source_path` (excluding `exclude`). Changing it, like changing `triggers`, forces a replace so the build re-runs.",
+				Computed:            true,
+			},
+			"env": schema.MapAttribute{
+				MarkdownDescription: "Environment variables set for every build command, e.g. `NODE_ENV`, `NEXT_TELEMETRY_DISABLED`. Merged on top of the provider process's own environment.",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"timeout": schema.StringAttribute{
+				MarkdownDescription: "Maximum duration to let each build command run, as a Go duration string (e.g. `\"10m\"`). A command still running after this is killed. Unset means no timeout.",
+				Optional:            true,
+			},
 			"data": schema.ListNestedAttribute{
 				Computed:            true,
-				MarkdownDescription: "Example identifier",
+				MarkdownDescription: "One entry per Lambda function built, refreshed on every Read to catch drift in the underlying artifacts.",
 				NestedObject: schema.NestedAttributeObject{
-					Attributes: map[string]schema.Attribute{
-						"path": schema.StringAttribute{
-							MarkdownDescription: "Example path",
-							Optional:            true,
-							Computed:            true,
-						},
-						"sha256": schema.StringAttribute{
-							MarkdownDescription: "Example sha256",
-							Optional:            true,
-							Computed:            true,
-						},
-						"function_name": schema.StringAttribute{
-							MarkdownDescription: "Example function",
-							Optional:            true,
-							Computed:            true,
-						},
-					},
+					Attributes: artifactAttributes(),
+				},
+			},
+			"artifacts_baseline": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Internal copy of `data` as recorded at the end of the last successful build. Unlike `data`, Read never rewrites this, so ModifyPlan can compare it against the artifacts on disk to detect drift (a rebuild or deletion that happened outside Terraform) even though Terraform always refreshes state before planning. Not meant to be set in configuration.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: artifactAttributes(),
 				},
 			},
 		},
 	}
 }
 
+// ModifyPlan recomputes source_hash from the current contents of
+// source_path and, on update, compares it and triggers against the prior
+// state: a change in either forces a replace, so the build commands re-run
+// the same way `null_resource.triggers` forces a re-run of its provisioners.
+func (r *BuildCommand) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Resource is being destroyed; nothing to plan.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan BuildCommandModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.SourcePath.IsNull() || plan.SourcePath.IsUnknown() {
+		return
+	}
+
+	exist, err := exists(plan.SourcePath.ValueString())
+	if err != nil || !exist {
+		// Create will surface a precise error for an invalid source path.
+		return
+	}
+
+	exclude := defaultSourceHashExclude
+	if !plan.Exclude.IsNull() && !plan.Exclude.IsUnknown() {
+		var excludeElements []types.String
+		diags := plan.Exclude.ElementsAs(ctx, &excludeElements, false)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		exclude = make([]string, len(excludeElements))
+		for i, e := range excludeElements {
+			exclude[i] = e.ValueString()
+		}
+	}
+
+	hash, err := computeSourceHash(plan.SourcePath.ValueString(), exclude)
+	if err != nil {
+		resp.Diagnostics.AddWarning("Could not compute source_hash", fmt.Sprintf("Skipping source_hash drift detection: %s", err.Error()))
+		return
+	}
+	plan.SourceHash = types.StringValue(hash)
+
+	// Fill in the package-manager-specific default commands when the user
+	// left `commands` unconfigured; it can't be a static schema default
+	// since it depends on the lockfile found under source_path.
+	var config BuildCommandModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if config.Commands.IsNull() {
+		manager := r.packageManager
+		if manager == "" {
+			manager = detectPackageManager(plan.SourcePath.ValueString())
+		}
+
+		commandsValue, diags := types.ListValueFrom(ctx, types.StringType, defaultCommandsForPackageManager(manager))
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		plan.Commands = commandsValue
+	}
+
+	// On create there is no prior state to compare against.
+	if !req.State.Raw.IsNull() {
+		var state BuildCommandModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if state.SourceHash.ValueString() != hash {
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("source_hash"))
+		}
+		if !plan.Triggers.Equal(state.Triggers) {
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("triggers"))
+		}
+
+		// Terraform always calls Read (refresh) before ModifyPlan, and Read
+		// already rewrites `data` to match whatever is on disk right now, so
+		// comparing against state.Data here would never see drift. Compare
+		// against artifacts_baseline instead: it is only ever written by
+		// Create/Update, so it still reflects the artifacts as they stood
+		// after the last successful build, and a live disk check against it
+		// is what actually forces Create to re-run and regenerate a rebuild
+		// or deletion that happened outside Terraform.
+		if !state.ArtifactsBaseline.IsNull() && !state.ArtifactsBaseline.IsUnknown() {
+			var baseline []buildCommandArtifact
+			diags := state.ArtifactsBaseline.ElementsAs(ctx, &baseline, false)
+			resp.Diagnostics.Append(diags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			_, drifted, err := refreshArtifacts(ctx, baseline, plan.IgnoreMissingArtifacts.ValueBool())
+			if err != nil {
+				resp.Diagnostics.AddWarning("Could not check artifacts for drift", fmt.Sprintf("Skipping artifact drift detection: %s", err.Error()))
+			} else if drifted {
+				resp.RequiresReplace = append(resp.RequiresReplace, path.Root("data"))
+			}
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
 func (r *BuildCommand) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	// Prevent panic if the provider has not been configured.
 	if req.ProviderData == nil {
 		return
 	}
 
-	executable, ok := req.ProviderData.(string)
+	data, ok := req.ProviderData.(providerResourceData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected string, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected providerResourceData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.executable = executable
+	r.executable = data.Executable
+	r.packageManager = data.PackageManager
 }
 
 func exists(path string) (bool, error) {
@@ -171,6 +356,25 @@ func (r *BuildCommand) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
+	// Resolve the package manager and executable to build with. Either may
+	// already be fixed by the provider config; otherwise fall back to
+	// auto-detecting from source_path's lockfile.
+	executable := r.executable
+	if executable == "" {
+		manager := r.packageManager
+		if manager == "" {
+			manager = detectPackageManager(data.SourcePath.ValueString())
+		}
+		executable = executableForPackageManager(manager)
+
+		err, version := detectExecutableVersion(executable)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Error detecting %s!", executable), "Error detecting executable version: "+err.Error())
+			return
+		}
+		tflog.Trace(ctx, fmt.Sprintf("Resolved package manager %s (executable %s, version %s)", manager, executable, version))
+	}
+
 	// Execute Commands with Executable
 	var elements []types.String
 	diags := data.Commands.ElementsAs(ctx, &elements, false)
@@ -179,18 +383,35 @@ func (r *BuildCommand) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
+	var envMap map[string]string
+	if !data.Env.IsNull() && !data.Env.IsUnknown() {
+		diags := data.Env.ElementsAs(ctx, &envMap, false)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	env := make([]string, 0, len(envMap))
+	for key, value := range envMap {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	var timeout time.Duration
+	if !data.Timeout.IsNull() && !data.Timeout.IsUnknown() && data.Timeout.ValueString() != "" {
+		timeout, err = time.ParseDuration(data.Timeout.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid timeout", fmt.Sprintf("Could not parse timeout %q: %s", data.Timeout.ValueString(), err.Error()))
+			return
+		}
+	}
+
 	for index, element := range elements {
 		tflog.Trace(ctx, fmt.Sprintf("Executing Item-%d: %s", index, element.ValueString()))
 		commandArgs := safeSplit(element.ValueString())
-		command := exec.Command(r.executable, commandArgs...)
-		command.Dir = data.SourcePath.ValueString()
-		result, err := command.Output()
-		if err != nil {
-			tflog.Debug(ctx, fmt.Sprintf("Failed command output: %s", result))
-			resp.Diagnostics.AddError(fmt.Sprintf("Could not execute Command: %s", command.String()), err.Error())
+		if err := runBuildCommand(ctx, executable, commandArgs, data.SourcePath.ValueString(), env, timeout, index); err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Could not execute command %d: %s", index, element.ValueString()), err.Error())
 			return
 		}
-		tflog.Trace(ctx, fmt.Sprintf("Result from Command-%d: %s", index, result))
 	}
 
 	nextJSStateFile := path2.Join(data.SourcePath.ValueString(), ".serverless", "serverless-state.json")
@@ -200,36 +421,30 @@ func (r *BuildCommand) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	plan, _ := os.ReadFile(nextJSStateFile)
-	var nextJSState interface{}
-	err = json.Unmarshal(plan, &nextJSState)
+	artifacts, err := readServerlessState(nextJSStateFile, data.SourcePath.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError("Unable to parse NextJS State", fmt.Sprintf("The File: %s could not be read and the following error was produced: %s.", nextJSStateFile, err.Error()))
 		return
 	}
 
-	tflog.Trace(ctx, fmt.Sprintf("Following nextJS State found: %v", nextJSState))
-
-	mapElements := map[string]attr.Value{
-		"path":          types.StringValue("value1"),
-		"sha256":        types.StringValue("value2"),
-		"function_name": types.StringValue("value3"),
-	}
-	elementTypes := map[string]attr.Type{
-		"path":          types.StringType,
-		"sha256":        types.StringType,
-		"function_name": types.StringType,
-	}
-	mapValue, diags := types.ObjectValue(elementTypes, mapElements)
-
-	if diags.HasError() {
-		tflog.Error(ctx, fmt.Sprintf("Failed to construct Data. %s", diags.Errors()))
-		return
+	tflog.Trace(ctx, fmt.Sprintf("Found %d function artifact(s) in %s", len(artifacts), nextJSStateFile))
+
+	listElements := make([]types.Object, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		mapValue, diags := types.ObjectValue(dataElementTypes, map[string]attr.Value{
+			"path":          types.StringValue(artifact.ArtifactPath),
+			"sha256":        types.StringValue(artifact.SHA256),
+			"function_name": types.StringValue(artifact.FunctionName),
+		})
+		if diags.HasError() {
+			resp.Diagnostics.Append(diags...)
+			return
+		}
+		listElements = append(listElements, mapValue)
 	}
 
-	listElements := []types.Object{mapValue}
 	tflog.Trace(ctx, "Creating list Value...")
-	listValue, diags := types.ListValueFrom(ctx, mapValue.Type(ctx), listElements)
+	listValue, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: dataElementTypes}, listElements)
 
 	if diags.HasError() {
 		tflog.Error(ctx, fmt.Sprintf("Failed to construct Data. %s", diags.Errors()))
@@ -238,6 +453,7 @@ func (r *BuildCommand) Create(ctx context.Context, req resource.CreateRequest, r
 
 	tflog.Trace(ctx, "Filling data...")
 	data.Data = listValue
+	data.ArtifactsBaseline = listValue
 
 	tflog.Trace(ctx, fmt.Sprintf("Saved Data: %s", data.Data.String()))
 
@@ -249,6 +465,57 @@ func (r *BuildCommand) Create(ctx context.Context, req resource.CreateRequest, r
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// refreshArtifacts rehashes every stored artifact, dropping (or, if
+// ignoreMissing, keeping as-is) any entry whose file has disappeared and
+// updating the sha256 of any entry whose file changed. drifted reports
+// whether anything changed, so callers that can force a replacement (see
+// ModifyPlan) know to do so: rewriting `data` here alone does not make
+// Terraform re-run the build commands that would regenerate a missing or
+// stale artifact.
+func refreshArtifacts(ctx context.Context, stored []buildCommandArtifact, ignoreMissing bool) ([]buildCommandArtifact, bool, error) {
+	drifted := false
+	refreshed := make([]buildCommandArtifact, 0, len(stored))
+
+	for _, entry := range stored {
+		artifactPath := entry.Path.ValueString()
+		exist, err := exists(artifactPath)
+		if err != nil {
+			return nil, false, fmt.Errorf("could not stat artifact %s: %w", artifactPath, err)
+		}
+
+		if !exist {
+			if ignoreMissing {
+				refreshed = append(refreshed, entry)
+				continue
+			}
+			drifted = true
+			tflog.Warn(ctx, fmt.Sprintf("Artifact %s for function %s no longer exists; dropping it from state to force a replace", artifactPath, entry.FunctionName.ValueString()))
+			continue
+		}
+
+		sum, err := hashFile(artifactPath)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if sum != entry.SHA256.ValueString() {
+			drifted = true
+			tflog.Info(ctx, fmt.Sprintf("Artifact %s for function %s changed (sha256 %s -> %s)", artifactPath, entry.FunctionName.ValueString(), entry.SHA256.ValueString(), sum))
+			entry.SHA256 = types.StringValue(sum)
+		}
+
+		refreshed = append(refreshed, entry)
+	}
+
+	return refreshed, drifted, nil
+}
+
+// Read rehashes every artifact recorded in state so that drift introduced by
+// rebuilds that happened outside Terraform (or artifacts deleted entirely) is
+// reflected back into the plan. It deliberately leaves artifacts_baseline
+// untouched: ModifyPlan needs a copy of the last-known-good artifact list
+// that Read hasn't already patched up, or it would never see any drift to
+// act on.
 func (r *BuildCommand) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var data BuildCommandModel
 
@@ -259,13 +526,31 @@ func (r *BuildCommand) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	// If applicable, this is a great opportunity to initialize any necessary
-	// provider client data and make a call using it.
-	// httpResp, err := r.client.Do(httpReq)
-	// if err != nil {
-	//     resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read example, got error: %s", err))
-	//     return
-	// }
+	if data.Data.IsNull() || data.Data.IsUnknown() {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	var stored []buildCommandArtifact
+	diags := data.Data.ElementsAs(ctx, &stored, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	refreshed, _, err := refreshArtifacts(ctx, stored, data.IgnoreMissingArtifacts.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError("Could not refresh artifact state", err.Error())
+		return
+	}
+
+	listValue, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: dataElementTypes}, refreshed)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Data = listValue
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSafeSplit(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  []string
+	}{
+		"simple":        {"run-script build", []string{"run-script", "build"}},
+		"single-quoted": {"install --cache '.npm cache'", []string{"install", "--cache", ".npm cache"}},
+		"double-quoted": {`run-script "build and package"`, []string{"run-script", "build and package"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := safeSplit(tc.input)
+			if len(got) != len(tc.want) {
+				t.Fatalf("safeSplit(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("safeSplit(%q)[%d] = %q, want %q", tc.input, i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExists(t *testing.T) {
+	dir := t.TempDir()
+	present := filepath.Join(dir, "present.txt")
+	if err := os.WriteFile(present, []byte("x"), 0o600); err != nil {
+		t.Fatalf("could not write fixture file: %s", err)
+	}
+
+	ok, err := exists(present)
+	if err != nil || !ok {
+		t.Errorf("exists(%q) = (%v, %v), want (true, nil)", present, ok, err)
+	}
+
+	ok, err = exists(filepath.Join(dir, "missing.txt"))
+	if err != nil || ok {
+		t.Errorf("exists(missing) = (%v, %v), want (false, nil)", ok, err)
+	}
+}
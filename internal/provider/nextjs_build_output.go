@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	path2 "path"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &BuildOutput{}
+
+func NewBuildOutput() datasource.DataSource {
+	return &BuildOutput{}
+}
+
+// BuildOutput reads the artifacts produced by a serverless-nextjs build that
+// already ran outside of Terraform, mirroring the `data` list that
+// `nextjs_build_command` produces without re-running any build commands.
+type BuildOutput struct{}
+
+// BuildOutputModel describes the data source data model.
+type BuildOutputModel struct {
+	SourcePath types.String `tfsdk:"source_path"`
+	StateFile  types.String `tfsdk:"state_file"`
+	Data       types.List   `tfsdk:"data"`
+}
+
+func (d *BuildOutput) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_build_output"
+}
+
+func (d *BuildOutput) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads the artifacts from an already-built serverless-nextjs output directory, without running any build commands. Useful for CI pipelines that build outside Terraform but still need to feed the result into `aws_lambda_function`.",
+
+		Attributes: map[string]schema.Attribute{
+			"source_path": schema.StringAttribute{
+				MarkdownDescription: "Path to the built serverless-nextjs project, i.e. the directory containing `.serverless/serverless-state.json`.",
+				Required:            true,
+			},
+			"state_file": schema.StringAttribute{
+				MarkdownDescription: "Override path to the serverless-nextjs state file. Defaults to `.serverless/serverless-state.json` under `source_path`.",
+				Optional:            true,
+			},
+			"data": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "One entry per Lambda function found in the serverless-nextjs state.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"path": schema.StringAttribute{
+							MarkdownDescription: "Absolute path to the function's packaged artifact zip.",
+							Computed:            true,
+						},
+						"sha256": schema.StringAttribute{
+							MarkdownDescription: "SHA-256 digest of the artifact, suitable for `aws_lambda_function.source_code_hash`.",
+							Computed:            true,
+						},
+						"function_name": schema.StringAttribute{
+							MarkdownDescription: "Logical name of the function.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// resolveStateFile returns the serverless-state.json path to read for
+// sourcePath: override when set, otherwise the conventional path under
+// sourcePath's .serverless directory.
+func resolveStateFile(sourcePath, override string) string {
+	if override != "" {
+		return override
+	}
+	return path2.Join(sourcePath, ".serverless", "serverless-state.json")
+}
+
+func (d *BuildOutput) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data BuildOutputModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.SourcePath.IsNull() || data.SourcePath.IsUnknown() {
+		resp.Diagnostics.AddError("Source Path needed", "Source Path needed in order to read Build Output")
+		return
+	}
+
+	stateFileOverride := ""
+	if !data.StateFile.IsNull() && !data.StateFile.IsUnknown() {
+		stateFileOverride = data.StateFile.ValueString()
+	}
+	stateFile := resolveStateFile(data.SourcePath.ValueString(), stateFileOverride)
+
+	exist, err := exists(stateFile)
+	if !exist || err != nil {
+		resp.Diagnostics.AddError("State File is not Valid", fmt.Sprintf("The state file %s either does not exist, or Terraform cannot access it.", stateFile))
+		return
+	}
+
+	artifacts, err := readServerlessState(stateFile, data.SourcePath.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to parse NextJS State", fmt.Sprintf("The File: %s could not be read and the following error was produced: %s.", stateFile, err.Error()))
+		return
+	}
+
+	tflog.Trace(ctx, fmt.Sprintf("Found %d function artifact(s) in %s", len(artifacts), stateFile))
+
+	listElements := make([]types.Object, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		mapValue, diags := types.ObjectValue(dataElementTypes, map[string]attr.Value{
+			"path":          types.StringValue(artifact.ArtifactPath),
+			"sha256":        types.StringValue(artifact.SHA256),
+			"function_name": types.StringValue(artifact.FunctionName),
+		})
+		if diags.HasError() {
+			resp.Diagnostics.Append(diags...)
+			return
+		}
+		listElements = append(listElements, mapValue)
+	}
+
+	listValue, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: dataElementTypes}, listElements)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	data.Data = listValue
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildOutput_UsesDefaultStateFileUnderSourcePath(t *testing.T) {
+	sourcePath := t.TempDir()
+	serverlessDir := filepath.Join(sourcePath, ".serverless")
+	if err := os.MkdirAll(serverlessDir, 0o755); err != nil {
+		t.Fatalf("could not create .serverless dir: %s", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join("testdata", "serverless-state-multi.json"))
+	if err != nil {
+		t.Fatalf("could not read fixture: %s", err)
+	}
+	stateFile := filepath.Join(serverlessDir, "serverless-state.json")
+	if err := os.WriteFile(stateFile, raw, 0o600); err != nil {
+		t.Fatalf("could not write fixture state file: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourcePath, "api-lambda.zip"), []byte("api"), 0o600); err != nil {
+		t.Fatalf("could not write fixture artifact: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourcePath, "default-lambda.zip"), []byte("default"), 0o600); err != nil {
+		t.Fatalf("could not write fixture artifact: %s", err)
+	}
+
+	resolved := resolveStateFile(sourcePath, "")
+	if resolved != stateFile {
+		t.Fatalf("resolveStateFile(%q, \"\") = %q, want %q", sourcePath, resolved, stateFile)
+	}
+
+	artifacts, err := readServerlessState(resolved, sourcePath)
+	if err != nil {
+		t.Fatalf("readServerlessState returned unexpected error: %s", err)
+	}
+	if len(artifacts) != 2 {
+		t.Fatalf("expected 2 artifacts, got %d", len(artifacts))
+	}
+}
+
+func TestResolveStateFile(t *testing.T) {
+	sourcePath := filepath.Join("some", "source")
+
+	got := resolveStateFile(sourcePath, "")
+	want := filepath.Join(sourcePath, ".serverless", "serverless-state.json")
+	if got != want {
+		t.Errorf("resolveStateFile(%q, \"\") = %q, want %q", sourcePath, got, want)
+	}
+
+	override := filepath.Join("other", "state.json")
+	if got := resolveStateFile(sourcePath, override); got != override {
+		t.Errorf("resolveStateFile(%q, %q) = %q, want %q", sourcePath, override, got, override)
+	}
+}
+
+func TestBuildOutput_StateFileMissing(t *testing.T) {
+	// A source path with nothing under .serverless has no state file at the
+	// conventional path, which Read reports as an invalid state file.
+	sourcePath := t.TempDir()
+	stateFile := resolveStateFile(sourcePath, "")
+
+	exist, err := exists(stateFile)
+	if err != nil {
+		t.Fatalf("exists(%q) returned unexpected error: %s", stateFile, err)
+	}
+	if exist {
+		t.Fatalf("expected %q not to exist", stateFile)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import "path/filepath"
+
+// validPackageManagers are the package managers nextjs_build_command knows
+// how to drive. The zero value "" means "auto-detect".
+var validPackageManagers = []string{"npm", "yarn", "pnpm", "bun"}
+
+func isValidPackageManager(manager string) bool {
+	for _, m := range validPackageManagers {
+		if m == manager {
+			return true
+		}
+	}
+	return false
+}
+
+// detectPackageManager inspects sourcePath for a package manager's lockfile
+// and returns the corresponding manager name, defaulting to "npm" when none
+// is found.
+func detectPackageManager(sourcePath string) string {
+	locks := []struct {
+		file    string
+		manager string
+	}{
+		{"pnpm-lock.yaml", "pnpm"},
+		{"yarn.lock", "yarn"},
+		{"bun.lockb", "bun"},
+	}
+
+	for _, lock := range locks {
+		if ok, err := exists(filepath.Join(sourcePath, lock.file)); err == nil && ok {
+			return lock.manager
+		}
+	}
+
+	return "npm"
+}
+
+// executableForPackageManager returns the CLI binary used to drive manager.
+// Every supported manager's executable shares its name.
+func executableForPackageManager(manager string) string {
+	return manager
+}
+
+// defaultCommandsForPackageManager returns the install/build/package command
+// sequence run by nextjs_build_command when `commands` is left unconfigured.
+func defaultCommandsForPackageManager(manager string) []string {
+	switch manager {
+	case "yarn":
+		return []string{"install --frozen-lockfile", "run build", "run package"}
+	case "pnpm":
+		return []string{"install --frozen-lockfile", "run build", "run package"}
+	case "bun":
+		return []string{"install --frozen-lockfile", "run build", "run package"}
+	default:
+		return []string{"install --cache .npm --prefer-offline", "run-script build", "run-script package"}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectPackageManager(t *testing.T) {
+	tests := map[string]struct {
+		lockfile string
+		want     string
+	}{
+		"pnpm":    {"pnpm-lock.yaml", "pnpm"},
+		"yarn":    {"yarn.lock", "yarn"},
+		"bun":     {"bun.lockb", "bun"},
+		"npm":     {"package-lock.json", "npm"},
+		"no lock": {"", "npm"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tc.lockfile != "" {
+				if err := os.WriteFile(filepath.Join(dir, tc.lockfile), []byte(""), 0o600); err != nil {
+					t.Fatalf("could not write lockfile: %s", err)
+				}
+			}
+
+			if got := detectPackageManager(dir); got != tc.want {
+				t.Errorf("detectPackageManager() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsValidPackageManager(t *testing.T) {
+	for _, m := range []string{"npm", "yarn", "pnpm", "bun"} {
+		if !isValidPackageManager(m) {
+			t.Errorf("expected %q to be a valid package manager", m)
+		}
+	}
+	if isValidPackageManager("deno") {
+		t.Error("expected deno to be rejected as a package manager")
+	}
+}
+
+func TestDefaultCommandsForPackageManager(t *testing.T) {
+	for _, m := range []string{"npm", "yarn", "pnpm", "bun"} {
+		commands := defaultCommandsForPackageManager(m)
+		if len(commands) != 3 {
+			t.Errorf("expected 3 default commands for %s, got %d: %v", m, len(commands), commands)
+		}
+	}
+}
@@ -29,7 +29,17 @@ type NextJSProvider struct {
 
 // NextJSProviderModel describes the provider data model.
 type NextJSProviderModel struct {
-	Executable types.String `tfsdk:"executable"`
+	Executable     types.String `tfsdk:"executable"`
+	PackageManager types.String `tfsdk:"package_manager"`
+}
+
+// providerResourceData is passed as ProviderData to resources and data
+// sources. Executable and PackageManager are empty when they could not be
+// resolved without knowing a resource's own source_path, in which case the
+// resource resolves them itself from its source_path's lockfiles.
+type providerResourceData struct {
+	Executable     string
+	PackageManager string
 }
 
 func (p *NextJSProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -41,7 +51,11 @@ func (p *NextJSProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"executable": schema.StringAttribute{
-				MarkdownDescription: "The 'npm' executable used to run the build commands for nextjs with.",
+				MarkdownDescription: "The executable used to run the build commands for nextjs with. Defaults to the binary matching `package_manager`; if that is also unset, each `nextjs_build_command` resource uses the binary of the package manager it auto-detects under its own `source_path`.",
+				Optional:            true,
+			},
+			"package_manager": schema.StringAttribute{
+				MarkdownDescription: "The package manager to drive builds with: `npm`, `yarn`, `pnpm`, or `bun`. When unset, each `nextjs_build_command` resource auto-detects it from the lockfile found under its own `source_path` (`pnpm-lock.yaml` → pnpm, `yarn.lock` → yarn, `bun.lockb` → bun, otherwise npm).",
 				Optional:            true,
 			},
 		},
@@ -68,24 +82,43 @@ func (p *NextJSProvider) Configure(ctx context.Context, req provider.ConfigureRe
 
 	// Configuration values are now available.
 	tflog.Trace(ctx, "Configuration init...")
-	if data.Executable.IsNull() {
-		err, version := detectExecutableVersion("npm")
-		if err != nil {
-			resp.Diagnostics.AddError("Error detecting NPM!", "Error detecting NPM version: "+err.Error())
+
+	packageManager := ""
+	if !data.PackageManager.IsNull() {
+		packageManager = data.PackageManager.ValueString()
+		if !isValidPackageManager(packageManager) {
+			resp.Diagnostics.AddError(
+				"Invalid package_manager",
+				fmt.Sprintf("package_manager must be one of %v, got: %q.", validPackageManagers, packageManager),
+			)
 			return
 		}
-		data.Executable = types.StringValue("npm")
-		tflog.Trace(ctx, fmt.Sprintf("Executable Version detected: %s", version))
-	} else {
-		err, version := detectExecutableVersion(data.Executable.ValueString())
+	}
+
+	executable := ""
+	switch {
+	case !data.Executable.IsNull():
+		executable = data.Executable.ValueString()
+	case packageManager != "":
+		executable = executableForPackageManager(packageManager)
+	}
+
+	// When neither executable nor package_manager is configured, resolving
+	// the binary requires knowing a resource's source_path, so it is
+	// deferred to each nextjs_build_command resource.
+	if executable != "" {
+		err, version := detectExecutableVersion(executable)
 		if err != nil {
-			resp.Diagnostics.AddError(fmt.Sprintf("Error detecting %s!", data.Executable.ValueString()), "Error detecting NPM version: "+err.Error())
+			resp.Diagnostics.AddError(fmt.Sprintf("Error detecting %s!", executable), "Error detecting executable version: "+err.Error())
 			return
 		}
 		tflog.Trace(ctx, fmt.Sprintf("Executable Version detected: %s", version))
 	}
 
-	resp.ResourceData = data.Executable.ValueString()
+	resp.ResourceData = providerResourceData{
+		Executable:     executable,
+		PackageManager: packageManager,
+	}
 }
 
 func (p *NextJSProvider) Resources(ctx context.Context) []func() resource.Resource {
@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// serverlessState mirrors the subset of serverless-nextjs's
+// `.serverless/serverless-state.json` that we care about: the logical
+// function definitions and the artifact each one packages to.
+type serverlessState struct {
+	Service struct {
+		Functions map[string]struct {
+			Name    string `json:"name"`
+			Handler string `json:"handler"`
+			Package struct {
+				Artifact string `json:"artifact"`
+			} `json:"package"`
+		} `json:"functions"`
+	} `json:"service"`
+}
+
+// functionArtifact is a single Lambda-ready artifact produced by a
+// serverless-nextjs build, resolved to an absolute path with its contents
+// hashed.
+type functionArtifact struct {
+	FunctionName string
+	ArtifactPath string
+	SHA256       string
+}
+
+// readServerlessState parses the serverless-nextjs state file at stateFile
+// and returns one functionArtifact per entry in service.functions, resolving
+// each artifact path relative to sourcePath and hashing its contents.
+//
+// Functions are returned sorted by their service.functions key (not by the
+// returned FunctionName) so callers get a stable ordering
+// across runs, independent of Go's randomized map iteration.
+func readServerlessState(stateFile, sourcePath string) ([]functionArtifact, error) {
+	raw, err := os.ReadFile(stateFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", stateFile, err)
+	}
+
+	var state serverlessState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", stateFile, err)
+	}
+
+	names := make([]string, 0, len(state.Service.Functions))
+	for name := range state.Service.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	artifacts := make([]functionArtifact, 0, len(names))
+	for _, name := range names {
+		fn := state.Service.Functions[name]
+		if fn.Package.Artifact == "" {
+			return nil, fmt.Errorf("function %q has no package artifact in %s", name, stateFile)
+		}
+
+		artifactPath := fn.Package.Artifact
+		if !filepath.IsAbs(artifactPath) {
+			artifactPath = filepath.Join(sourcePath, artifactPath)
+		}
+
+		sum, err := hashFile(artifactPath)
+		if err != nil {
+			return nil, fmt.Errorf("function %q: %w", name, err)
+		}
+
+		functionName := fn.Name
+		if functionName == "" {
+			functionName = name
+		}
+
+		artifacts = append(artifacts, functionArtifact{
+			FunctionName: functionName,
+			ArtifactPath: artifactPath,
+			SHA256:       sum,
+		})
+	}
+
+	return artifacts, nil
+}
+
+// hashFile streams the contents of path through SHA-256, returning the
+// hex-encoded digest.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("artifact %s does not exist", path)
+		}
+		return "", fmt.Errorf("could not open artifact %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("could not hash artifact %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
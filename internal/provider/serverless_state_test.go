@@ -0,0 +1,95 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func copyFixture(t *testing.T, name, destDir string) string {
+	t.Helper()
+
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("could not read fixture %s: %s", name, err)
+	}
+
+	dest := filepath.Join(destDir, name)
+	if err := os.WriteFile(dest, raw, 0o600); err != nil {
+		t.Fatalf("could not write fixture %s: %s", name, err)
+	}
+
+	return dest
+}
+
+func TestReadServerlessState_Multi(t *testing.T) {
+	sourcePath := t.TempDir()
+	stateFile := copyFixture(t, "serverless-state-multi.json", sourcePath)
+
+	apiContents := []byte("api-lambda-contents")
+	defaultContents := []byte("default-lambda-contents")
+	if err := os.WriteFile(filepath.Join(sourcePath, "api-lambda.zip"), apiContents, 0o600); err != nil {
+		t.Fatalf("could not write fixture artifact: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourcePath, "default-lambda.zip"), defaultContents, 0o600); err != nil {
+		t.Fatalf("could not write fixture artifact: %s", err)
+	}
+
+	artifacts, err := readServerlessState(stateFile, sourcePath)
+	if err != nil {
+		t.Fatalf("readServerlessState returned unexpected error: %s", err)
+	}
+
+	if len(artifacts) != 2 {
+		t.Fatalf("expected 2 artifacts, got %d", len(artifacts))
+	}
+
+	// Functions come back sorted by their service.functions key, so
+	// ApiLambdaFunction precedes DefaultLambdaFunction.
+	if artifacts[0].FunctionName != "my-app-dev-api" {
+		t.Errorf("expected first function name my-app-dev-api, got %s", artifacts[0].FunctionName)
+	}
+	wantAPISum := sha256Sum(apiContents)
+	if artifacts[0].SHA256 != wantAPISum {
+		t.Errorf("expected sha256 %s, got %s", wantAPISum, artifacts[0].SHA256)
+	}
+	if artifacts[0].ArtifactPath != filepath.Join(sourcePath, "api-lambda.zip") {
+		t.Errorf("expected path %s, got %s", filepath.Join(sourcePath, "api-lambda.zip"), artifacts[0].ArtifactPath)
+	}
+
+	if artifacts[1].FunctionName != "my-app-dev-default" {
+		t.Errorf("expected second function name my-app-dev-default, got %s", artifacts[1].FunctionName)
+	}
+	wantDefaultSum := sha256Sum(defaultContents)
+	if artifacts[1].SHA256 != wantDefaultSum {
+		t.Errorf("expected sha256 %s, got %s", wantDefaultSum, artifacts[1].SHA256)
+	}
+}
+
+func TestReadServerlessState_MissingArtifact(t *testing.T) {
+	sourcePath := t.TempDir()
+	stateFile := copyFixture(t, "serverless-state-missing-artifact.json", sourcePath)
+
+	if _, err := readServerlessState(stateFile, sourcePath); err == nil {
+		t.Fatal("expected an error for a missing artifact, got nil")
+	}
+}
+
+func TestReadServerlessState_Malformed(t *testing.T) {
+	sourcePath := t.TempDir()
+	stateFile := copyFixture(t, "serverless-state-malformed.json", sourcePath)
+
+	if _, err := readServerlessState(stateFile, sourcePath); err == nil {
+		t.Fatal("expected an error for malformed JSON, got nil")
+	}
+}
+
+func sha256Sum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,95 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultSourceHashExclude lists the top-level directories under
+// source_path that are excluded from source_hash by default: build
+// output and package manager caches that are regenerated on every
+// build and would otherwise make the hash change without any real
+// source edit.
+var defaultSourceHashExclude = []string{"node_modules", ".next", ".serverless", ".npm"}
+
+// excludeDefaultValues returns defaultSourceHashExclude as attr.Value, for use
+// as the `exclude` attribute's schema default.
+func excludeDefaultValues() []attr.Value {
+	values := make([]attr.Value, len(defaultSourceHashExclude))
+	for i, e := range defaultSourceHashExclude {
+		values[i] = types.StringValue(e)
+	}
+	return values
+}
+
+// computeSourceHash walks sourcePath and returns a SHA-256 digest over the
+// relative path and contents of every file found, skipping any top-level
+// entry named in exclude. filepath.Walk visits entries in lexical order, so
+// the result is stable across runs as long as the tree itself is unchanged.
+func computeSourceHash(sourcePath string, exclude []string) (string, error) {
+	excludeSet := make(map[string]struct{}, len(exclude))
+	for _, e := range exclude {
+		excludeSet[e] = struct{}{}
+	}
+
+	h := sha256.New()
+	err := filepath.Walk(sourcePath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(sourcePath, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+
+		top := rel
+		if idx := strings.IndexRune(rel, filepath.Separator); idx >= 0 {
+			top = rel[:idx]
+		}
+		if _, excluded := excludeSet[top]; excluded {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		if _, err := fmt.Fprintln(h, filepath.ToSlash(rel)); err != nil {
+			return err
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not hash source tree %s: %w", sourcePath, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
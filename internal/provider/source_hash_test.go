@@ -0,0 +1,86 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, contents := range files {
+		p := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("could not create dir for %s: %s", rel, err)
+		}
+		if err := os.WriteFile(p, []byte(contents), 0o600); err != nil {
+			t.Fatalf("could not write %s: %s", rel, err)
+		}
+	}
+}
+
+func TestComputeSourceHash_IsDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"pages/index.tsx": "export default function Home() {}",
+		"package.json":    `{"name": "app"}`,
+	})
+
+	first, err := computeSourceHash(dir, defaultSourceHashExclude)
+	if err != nil {
+		t.Fatalf("computeSourceHash returned unexpected error: %s", err)
+	}
+	second, err := computeSourceHash(dir, defaultSourceHashExclude)
+	if err != nil {
+		t.Fatalf("computeSourceHash returned unexpected error: %s", err)
+	}
+	if first != second {
+		t.Errorf("expected stable hash across runs, got %s and %s", first, second)
+	}
+}
+
+func TestComputeSourceHash_ChangesWithSourceEdits(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"pages/index.tsx": "v1"})
+
+	before, err := computeSourceHash(dir, defaultSourceHashExclude)
+	if err != nil {
+		t.Fatalf("computeSourceHash returned unexpected error: %s", err)
+	}
+
+	writeTree(t, dir, map[string]string{"pages/index.tsx": "v2"})
+
+	after, err := computeSourceHash(dir, defaultSourceHashExclude)
+	if err != nil {
+		t.Fatalf("computeSourceHash returned unexpected error: %s", err)
+	}
+
+	if before == after {
+		t.Error("expected hash to change after editing a source file")
+	}
+}
+
+func TestComputeSourceHash_IgnoresExcludedDirectories(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"pages/index.tsx": "v1"})
+
+	before, err := computeSourceHash(dir, defaultSourceHashExclude)
+	if err != nil {
+		t.Fatalf("computeSourceHash returned unexpected error: %s", err)
+	}
+
+	// Regenerating node_modules shouldn't affect the hash: it's excluded by default.
+	writeTree(t, dir, map[string]string{"node_modules/left-pad/index.js": "module.exports = () => {}"})
+
+	after, err := computeSourceHash(dir, defaultSourceHashExclude)
+	if err != nil {
+		t.Fatalf("computeSourceHash returned unexpected error: %s", err)
+	}
+
+	if before != after {
+		t.Error("expected hash to ignore changes under an excluded directory")
+	}
+}